@@ -1,17 +1,22 @@
 package main
 
 import (
-	"bytes"
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
-	"net/url"
-	"strings"
-	"sync"
+	"regexp"
 	"time"
+
+	"github.com/holys/lgtm-gitlab/store"
+	"github.com/holys/lgtm-gitlab/vcs"
+	"github.com/holys/lgtm-gitlab/vcs/gitea"
+	"github.com/holys/lgtm-gitlab/vcs/github"
+	"github.com/holys/lgtm-gitlab/vcs/gitlab"
 )
 
 func init() {
@@ -21,47 +26,130 @@ func init() {
 var (
 	privateToken = flag.String("private_token", "", "gitlab private token which used to accept merge request. can be found in https://your.gitlab.com/profile/account")
 	gitlabURL    = flag.String("gitlab_url", "", "e.g. https://your.gitlab.com")
-)
 
-const (
-	ValidLGTMCount = 2 // 满足条件的LGTM 数量
+	giteaURL   = flag.String("gitea_url", "", "e.g. https://your.gitea.io")
+	giteaToken = flag.String("gitea_token", "", "gitea API token")
+
+	githubURL   = flag.String("github_url", "", "github API base url, defaults to https://api.github.com. set for GitHub Enterprise")
+	githubToken = flag.String("github_token", "", "github personal access token")
+
+	provider = flag.String("provider", "gitlab", "VCS provider used when a webhook doesn't identify itself (gitlab, gitea or github)")
+
+	minApprovers  = flag.Int("min_approvers", 2, "number of distinct approvers required before a merge request is accepted")
+	approvalRegex = flag.String("approval_regex", `(?i)^lgtm`, "regexp a note must match to count as an approval")
+	excludeAuthor = flag.Bool("exclude_author", true, "do not let the merge request author's own notes count as an approval")
+
+	webhookSecret      = flag.String("webhook_secret", "", "shared secret GitLab must send in the X-Gitlab-Token header of every webhook request; GitLab requests that don't match are rejected (Gitea and GitHub webhooks are not verified)")
+	webhookSecretsFile = flag.String("webhook_secrets_file", "", "path to a JSON file of {\"project\": \"secret\"} overrides, since each project can set its own webhook token")
+
+	storeURL        = flag.String("store", "memory", `where to persist pending-merge state: "memory" or a redis://host:port/db URL`)
+	pendingMergeTTL = flag.Duration("pending_merge_ttl", 24*time.Hour, "how long a pending merge is kept before expiring, in case its MR is closed without its pipeline ever reporting back")
+
+	adminToken = flag.String("admin_token", "", "shared secret required in the X-Admin-Token header to reach /admin/pending; the endpoint is disabled if unset")
 )
 
 var (
-	ErrInvalidRequest     = errors.New("invalid request body")
-	ErrInvalidContentType = errors.New("invalid content type")
-	RespOK                = []byte("OK")
-
-	ObjectNote               = "note"
-	NoteableTypeMergeRequest = "MergeRequest"
-	NoteLGTM                 = "LGTM"
-	StatusCanbeMerged        = "can_be_merged"
+	ErrInvalidContentType  = fmt.Errorf("invalid content type")
+	ErrInvalidWebhookToken = errors.New("invalid webhook token")
+	RespOK                 = []byte("OK")
 )
 
-var (
-	mutex sync.RWMutex
-	// map[merge_request_id][count]
-	lgtmCount = make(map[int]int)
+// projectWebhookSecrets holds per-project overrides loaded from
+// --webhook_secrets_file, keyed by vcs.Event.Project.
+var projectWebhookSecrets = make(map[string]string)
 
-	glURL *url.URL
-)
+func loadWebhookSecrets(path string) error {
+	if path == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &projectWebhookSecrets)
+}
+
+// effectiveWebhookSecret returns the secret a webhook for project must
+// carry: its --webhook_secrets_file override if one is configured, else
+// the global --webhook_secret (which may itself be "" to mean "no
+// verification").
+func effectiveWebhookSecret(project string) string {
+	if secret, overridden := projectWebhookSecrets[project]; overridden {
+		return secret
+	}
+	return *webhookSecret
+}
+
+// providers holds every VCS backend this deployment has credentials
+// for, keyed by the name returned from Provider.Name.
+var providers = make(map[string]vcs.Provider)
+
+// pendingStore holds merge requests that met their LGTM threshold while
+// their pipeline was still running; they are merged for real once the
+// pipeline succeeds. It survives restarts when --store points at redis.
+var pendingStore store.Store
+
+// newStore builds the Store named by --store: "memory", or a
+// redis://host:port/db URL.
+func newStore(storeURL string) (store.Store, error) {
+	if storeURL == "" || storeURL == "memory" {
+		return store.NewMemory(), nil
+	}
+	return store.NewRedis(storeURL)
+}
 
 func main() {
 	flag.Parse()
 
-	if *privateToken == "" {
-		fmt.Println("private token is required")
+	if err := loadWebhookSecrets(*webhookSecretsFile); err != nil {
+		fmt.Println("webhook_secrets_file:", err.Error())
 		return
 	}
-	if *gitlabURL == "" {
-		fmt.Println("gitlab url is required")
+
+	s, err := newStore(*storeURL)
+	if err != nil {
+		fmt.Println("store:", err.Error())
 		return
 	}
+	pendingStore = s
 
-	parseURL(*gitlabURL)
+	if *gitlabURL != "" && *privateToken != "" {
+		p, err := gitlab.New(*gitlabURL, *privateToken)
+		if err != nil {
+			fmt.Println("gitlab provider:", err.Error())
+			return
+		}
+		providers[p.Name()] = p
+	}
+	if *giteaURL != "" && *giteaToken != "" {
+		p, err := gitea.New(*giteaURL, *giteaToken)
+		if err != nil {
+			fmt.Println("gitea provider:", err.Error())
+			return
+		}
+		providers[p.Name()] = p
+	}
+	if *githubToken != "" {
+		p, err := github.New(*githubURL, *githubToken)
+		if err != nil {
+			fmt.Println("github provider:", err.Error())
+			return
+		}
+		providers[p.Name()] = p
+	}
+
+	if len(providers) == 0 {
+		fmt.Println("at least one provider must be configured, e.g. --gitlab_url and --private_token")
+		return
+	}
+	if _, ok := providers[*provider]; !ok {
+		fmt.Printf("--provider=%s has no matching credentials configured\n", *provider)
+		return
+	}
 
 	fmt.Println("start http server")
 	http.HandleFunc("/gitlab/hook", LGTM)
+	http.HandleFunc("/admin/pending", adminPending)
 	go func() {
 		http.ListenAndServe(":8989", nil)
 	}()
@@ -69,12 +157,25 @@ func main() {
 	<-(chan struct{})(nil)
 }
 
-func parseURL(urlStr string) {
-	var err error
-	glURL, err = url.Parse(urlStr)
-	if err != nil {
-		panic(err.Error())
+// providerFor picks the provider that should handle r, preferring the
+// event-specific header GitLab, Gitea and GitHub each send and falling
+// back to --provider for requests that carry none of them.
+func providerFor(r *http.Request) (vcs.Provider, error) {
+	name := *provider
+	switch {
+	case r.Header.Get("X-Gitlab-Event") != "":
+		name = "gitlab"
+	case r.Header.Get("X-Gitea-Event") != "":
+		name = "gitea"
+	case r.Header.Get("X-GitHub-Event") != "":
+		name = "github"
+	}
+
+	p, ok := providers[name]
+	if !ok {
+		return nil, fmt.Errorf("no provider configured for %q", name)
 	}
+	return p, nil
 }
 
 func LGTM(w http.ResponseWriter, r *http.Request) {
@@ -83,9 +184,13 @@ func LGTM(w http.ResponseWriter, r *http.Request) {
 	var errRet error
 	defer func() {
 		if errRet != nil {
+			status := http.StatusBadRequest
+			if errRet == ErrInvalidWebhookToken {
+				status = http.StatusUnauthorized
+			}
 			errMsg := fmt.Sprintf("error occurs:%s", errRet.Error())
 			log.Println(errMsg)
-			w.WriteHeader(http.StatusBadRequest)
+			w.WriteHeader(status)
 			fmt.Fprintf(w, errMsg)
 			return
 		}
@@ -97,228 +202,214 @@ func LGTM(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if r.Body == nil {
-		errRet = ErrInvalidRequest
+		errRet = fmt.Errorf("invalid request body")
 		return
 	}
 
-	var comment Comment
-	if err := json.NewDecoder(r.Body).Decode(&comment); err != nil {
+	p, err := providerFor(r)
+	if err != nil {
 		errRet = err
 		return
 	}
 
-	checkLgtm(comment)
+	v, isVerifier := p.(vcs.WebhookVerifier)
+
+	// Common case: no --webhook_secrets_file configured, so the secret
+	// to check is known up front. Verify before decoding the body, same
+	// as before per-project overrides existed.
+	if isVerifier && len(projectWebhookSecrets) == 0 {
+		if secret := *webhookSecret; secret != "" {
+			if !v.VerifyWebhook(r, secret) {
+				errRet = ErrInvalidWebhookToken
+				return
+			}
+		}
+	}
+
+	// A per-project override is keyed by ev.Project, which we don't know
+	// until the payload is decoded, so that case has to verify after.
+	ev, parseErr := p.ParseWebhook(r)
+
+	if isVerifier && len(projectWebhookSecrets) > 0 {
+		if secret := effectiveWebhookSecret(ev.Project); secret != "" {
+			if !v.VerifyWebhook(r, secret) {
+				errRet = ErrInvalidWebhookToken
+				return
+			}
+		}
+	}
+
+	if parseErr == vcs.ErrUnhandledEvent {
+		return
+	}
+	if parseErr != nil {
+		errRet = parseErr
+		return
+	}
+
+	switch ev.Kind {
+	case vcs.KindNote:
+		errRet = checkLgtm(p, ev)
+	case vcs.KindPipeline:
+		errRet = checkPipeline(p, ev)
+	}
 }
 
-func checkLgtm(comment Comment) error {
-	log.Printf("debug comment:%+v", comment)
-	if comment.ObjectKind != ObjectNote {
+func checkLgtm(p vcs.Provider, ev vcs.Event) error {
+	log.Printf("debug event:%+v", ev)
+	if ev.Kind != vcs.KindNote {
 		// unmatched, do nothing
 		return nil
 	}
 
-	if comment.ObjectAttributes.NoteableType != NoteableTypeMergeRequest {
+	if ev.NoteableType != vcs.NoteableTypeMergeRequest {
 		// unmatched, do nothing
 		return nil
 	}
 
-	if strings.ToUpper(comment.ObjectAttributes.Note) != NoteLGTM {
+	re, err := regexp.Compile(*approvalRegex)
+	if err != nil {
+		return fmt.Errorf("compile approval_regex: %s", err.Error())
+	}
+	if !re.MatchString(ev.Note) {
 		// unmatched, do nothing
 		return nil
 	}
 
-	// TODO: 检查评论LGTM的两个人 是不同的人
+	minCount := *minApprovers
+	exclAuthor := *excludeAuthor
+	if cfg, err := vcs.LoadLgtmConfig(p, ev.Project); err != nil {
+		log.Printf("load %s error: %s, falling back to flags", vcs.LgtmConfigPath, err.Error())
+	} else if cfg != nil {
+		if cfg.MinApprovers > 0 {
+			minCount = cfg.MinApprovers
+		}
+		if cfg.ApprovalRegex != "" {
+			if re, err = regexp.Compile(cfg.ApprovalRegex); err != nil {
+				return fmt.Errorf("compile project approval_regex: %s", err.Error())
+			}
+		}
+		if cfg.ExcludeAuthor != nil {
+			exclAuthor = *cfg.ExcludeAuthor
+		}
+	}
 
-	var canbeMerged bool
+	notes, err := p.ListNotes(ev.Project, ev.MergeRequestIID)
+	if err != nil {
+		return fmt.Errorf("list notes: %s", err.Error())
+	}
 
-	mutex.Lock()
-	if count, ok := lgtmCount[comment.MergeRequest.ID]; ok {
-		newCount := count + 1
-		if newCount >= ValidLGTMCount {
-			canbeMerged = true
-		}
-		lgtmCount[comment.MergeRequest.ID] = newCount
-	} else {
-		lgtmCount[comment.MergeRequest.ID] = 1
+	approvers := vcs.Approvers(notes, ev.MergeRequestAuthorID, exclAuthor, re)
+
+	log.Printf("%s %s!%d approvers: %+v", p.Name(), ev.Project, ev.MergeRequestIID, approvers)
+
+	if len(approvers) < minCount {
+		return nil
 	}
-	mutex.Unlock()
 
-	log.Printf("counter: %+v", lgtmCount)
+	if ev.MergeStatus != vcs.StatusCanBeMerged {
+		pm, ok := p.(vcs.PipelineMerger)
+		if !ok {
+			log.Printf("%s %s!%d has enough approvers but merge_status is %q", p.Name(), ev.Project, ev.MergeRequestIID, ev.MergeStatus)
+			return nil
+		}
+
+		log.Printf("%s %s!%d has enough approvers, pipeline still running: merge when it succeeds", p.Name(), ev.Project, ev.MergeRequestIID)
+		if err := pm.AcceptWhenPipelineSucceeds(ev.Project, ev.MergeRequestIID, ev.RemoveSourceBranch); err != nil {
+			return fmt.Errorf("accept merge request: %s", err.Error())
+		}
+		if err := pendingStore.Save(ev.Project, ev.MergeRequestIID, store.PendingMerge{
+			RemoveSourceBranch: ev.RemoveSourceBranch,
+		}, *pendingMergeTTL); err != nil {
+			return fmt.Errorf("save pending merge: %s", err.Error())
+		}
+		return nil
+	}
 
-	if canbeMerged && comment.MergeRequest.MergeStatus == StatusCanbeMerged {
-		log.Printf("The MR can be merged. ")
-		acceptMergeRequest(comment.ProjectID, comment.MergeRequest.ID, comment.MergeRequest.MergeParams.ForceRemoveSourceBranch)
+	log.Printf("The MR can be merged. ")
+	if err := p.AcceptMergeRequest(ev.Project, ev.MergeRequestIID, ev.RemoveSourceBranch); err != nil {
+		return fmt.Errorf("accept merge request: %s", err.Error())
+	}
+	if err := p.PostComment(ev.Project, ev.MergeRequestIID, summaryComment(approvers)); err != nil {
+		log.Printf("post comment error:%s", err.Error())
 	}
 
 	return nil
 }
 
-func acceptMergeRequest(projectID int, mergeRequestID int, shouldRemoveSourceBranch bool) {
-	params := map[string]string{
-		"should_remove_source_branch": "true",
+// checkPipeline reacts to a pipeline/CI webhook for a branch whose
+// merge request already accumulated enough LGTMs but had to wait on CI
+// (see checkLgtm's merge_when_pipeline_succeeds path): it retries the
+// merge once the pipeline succeeds.
+func checkPipeline(p vcs.Provider, ev vcs.Event) error {
+	if ev.PipelineStatus != "success" {
+		return nil
 	}
-	bodyBytes, err := json.Marshal(params)
-	if err != nil {
-		log.Printf("json marshal error:%s", err.Error())
-		return
+
+	finder, ok := p.(vcs.BranchMergeRequestFinder)
+	if !ok {
+		return nil
 	}
 
-	glURL.Path = fmt.Sprintf("/api/v3/projects/%d/merge_requests/%d/merge", projectID, mergeRequestID)
-	req, err := http.NewRequest("PUT", glURL.String(), bytes.NewReader(bodyBytes))
+	mergeRequestIID, _, err := finder.FindMergeRequestBySourceBranch(ev.Project, ev.Ref)
+	if err == vcs.ErrNotFound {
+		return nil
+	}
 	if err != nil {
-		log.Printf("http NewRequest error:%s", err.Error())
-		return
+		return fmt.Errorf("find merge request for %s: %s", ev.Ref, err.Error())
 	}
-	req.Header.Set("Conntent-Type", "application/json")
-	// authenticate
-	req.Header.Set("PRIVATE-TOKEN", *privateToken) // my private token
 
-	resp, err := http.DefaultClient.Do(req)
+	pm, ok, err := pendingStore.Take(ev.Project, mergeRequestIID)
 	if err != nil {
-		log.Printf("execute request error:%s", err.Error())
-		return
+		return fmt.Errorf("take pending merge: %s", err.Error())
+	}
+	if !ok {
+		// no LGTM'd merge request was waiting on this pipeline
+		return nil
 	}
 
-	switch resp.StatusCode {
-	// 200
-	case http.StatusOK:
-		log.Printf("accept merge request successfully.")
-	// 405
-	case http.StatusMethodNotAllowed:
-		log.Printf("it has some conflicts and can not be merged")
-	// 406
-	case http.StatusNotAcceptable:
-		log.Printf("merge request is already merged or closed")
+	log.Printf("pipeline succeeded for %s %s!%d, retrying merge", p.Name(), ev.Project, mergeRequestIID)
+	if err := p.AcceptMergeRequest(ev.Project, mergeRequestIID, pm.RemoveSourceBranch); err != nil {
+		return fmt.Errorf("accept merge request: %s", err.Error())
 	}
+	return nil
 }
 
-// Comment represents gitlab comment events
-type Comment struct {
-	ObjectKind string `json:"object_kind"`
-	User       struct {
-		Name      string `json:"name"`
-		Username  string `json:"username"`
-		AvatarURL string `json:"avatar_url"`
-	} `json:"user"`
-	ProjectID int `json:"project_id"`
-	Project   struct {
-		Name              string      `json:"name"`
-		Description       string      `json:"description"`
-		WebURL            string      `json:"web_url"`
-		AvatarURL         interface{} `json:"avatar_url"`
-		GitSSHURL         string      `json:"git_ssh_url"`
-		GitHTTPURL        string      `json:"git_http_url"`
-		Namespace         string      `json:"namespace"`
-		VisibilityLevel   int         `json:"visibility_level"`
-		PathWithNamespace string      `json:"path_with_namespace"`
-		DefaultBranch     string      `json:"default_branch"`
-		Homepage          string      `json:"homepage"`
-		URL               string      `json:"url"`
-		SSHURL            string      `json:"ssh_url"`
-		HTTPURL           string      `json:"http_url"`
-	} `json:"project"`
-	ObjectAttributes struct {
-		ID                   int         `json:"id"`
-		Note                 string      `json:"note"`
-		NoteableType         string      `json:"noteable_type"`
-		AuthorID             int         `json:"author_id"`
-		CreatedAt            string      `json:"created_at"`
-		UpdatedAt            string      `json:"updated_at"`
-		ProjectID            int         `json:"project_id"`
-		Attachment           interface{} `json:"attachment"`
-		LineCode             interface{} `json:"line_code"`
-		CommitID             string      `json:"commit_id"`
-		NoteableID           int         `json:"noteable_id"`
-		StDiff               interface{} `json:"st_diff"`
-		System               bool        `json:"system"`
-		UpdatedByID          interface{} `json:"updated_by_id"`
-		Type                 interface{} `json:"type"`
-		Position             interface{} `json:"position"`
-		OriginalPosition     interface{} `json:"original_position"`
-		ResolvedAt           interface{} `json:"resolved_at"`
-		ResolvedByID         interface{} `json:"resolved_by_id"`
-		DiscussionID         string      `json:"discussion_id"`
-		OriginalDiscussionID interface{} `json:"original_discussion_id"`
-		URL                  string      `json:"url"`
-	} `json:"object_attributes"`
-	Repository struct {
-		Name        string `json:"name"`
-		URL         string `json:"url"`
-		Description string `json:"description"`
-		Homepage    string `json:"homepage"`
-	} `json:"repository"`
-	MergeRequest struct {
-		ID              int         `json:"id"`
-		TargetBranch    string      `json:"target_branch"`
-		SourceBranch    string      `json:"source_branch"`
-		SourceProjectID int         `json:"source_project_id"`
-		AuthorID        int         `json:"author_id"`
-		AssigneeID      int         `json:"assignee_id"`
-		Title           string      `json:"title"`
-		CreatedAt       string      `json:"created_at"`
-		UpdatedAt       string      `json:"updated_at"`
-		MilestoneID     interface{} `json:"milestone_id"`
-		State           string      `json:"state"`
-		MergeStatus     string      `json:"merge_status"`
-		TargetProjectID int         `json:"target_project_id"`
-		Iid             int         `json:"iid"`
-		Description     string      `json:"description"`
-		Position        int         `json:"position"`
-		LockedAt        interface{} `json:"locked_at"`
-		UpdatedByID     interface{} `json:"updated_by_id"`
-		MergeError      interface{} `json:"merge_error"`
-		MergeParams     struct {
-			ForceRemoveSourceBranch bool `json:"force_remove_source_branch"`
-		} `json:"merge_params"`
-		MergeWhenBuildSucceeds   bool        `json:"merge_when_build_succeeds"`
-		MergeUserID              interface{} `json:"merge_user_id"`
-		MergeCommitSha           interface{} `json:"merge_commit_sha"`
-		DeletedAt                interface{} `json:"deleted_at"`
-		InProgressMergeCommitSha interface{} `json:"in_progress_merge_commit_sha"`
-		Source                   struct {
-			Name              string `json:"name"`
-			Description       string `json:"description"`
-			WebURL            string `json:"web_url"`
-			AvatarURL         string `json:"avatar_url"`
-			GitSSHURL         string `json:"git_ssh_url"`
-			GitHTTPURL        string `json:"git_http_url"`
-			Namespace         string `json:"namespace"`
-			VisibilityLevel   int    `json:"visibility_level"`
-			PathWithNamespace string `json:"path_with_namespace"`
-			DefaultBranch     string `json:"default_branch"`
-			Homepage          string `json:"homepage"`
-			URL               string `json:"url"`
-			SSHURL            string `json:"ssh_url"`
-			HTTPURL           string `json:"http_url"`
-		} `json:"source"`
-		Target struct {
-			Name              string      `json:"name"`
-			Description       string      `json:"description"`
-			WebURL            string      `json:"web_url"`
-			AvatarURL         interface{} `json:"avatar_url"`
-			GitSSHURL         string      `json:"git_ssh_url"`
-			GitHTTPURL        string      `json:"git_http_url"`
-			Namespace         string      `json:"namespace"`
-			VisibilityLevel   int         `json:"visibility_level"`
-			PathWithNamespace string      `json:"path_with_namespace"`
-			DefaultBranch     string      `json:"default_branch"`
-			Homepage          string      `json:"homepage"`
-			URL               string      `json:"url"`
-			SSHURL            string      `json:"ssh_url"`
-			HTTPURL           string      `json:"http_url"`
-		} `json:"target"`
-		LastCommit struct {
-			ID        string    `json:"id"`
-			Message   string    `json:"message"`
-			Timestamp time.Time `json:"timestamp"`
-			URL       string    `json:"url"`
-			Author    struct {
-				Name  string `json:"name"`
-				Email string `json:"email"`
-			} `json:"author"`
-		} `json:"last_commit"`
-		WorkInProgress bool `json:"work_in_progress"`
-	} `json:"merge_request"`
+// adminPending serves the current set of pending merges on GET, for
+// operators debugging a stuck merge_when_pipeline_succeeds wait, and
+// clears it on POST/DELETE, in case a pending merge is stuck for good
+// (e.g. its pipeline was deleted rather than rerun). It is gated on
+// --admin_token since it can both leak in-flight MR state and wipe it.
+func adminPending(w http.ResponseWriter, r *http.Request) {
+	if *adminToken == "" {
+		http.Error(w, "admin endpoint disabled: set --admin_token to enable /admin/pending", http.StatusNotFound)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Admin-Token")), []byte(*adminToken)) != 1 {
+		http.Error(w, "invalid admin token", http.StatusUnauthorized)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		pending, err := pendingStore.List()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(pending)
+	case http.MethodPost, http.MethodDelete:
+		if err := pendingStore.Reset(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Write(RespOK)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
 }
 
-// 后续支持 redis. HINCR lgtm merge_id 1
+func summaryComment(approvers []string) string {
+	return fmt.Sprintf("Approved by %d approver(s): %v. Merging.", len(approvers), approvers)
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"sync"
+	"time"
+)
+
+// Memory is an in-process Store. Its state is lost on restart and isn't
+// shared across replicas; use Redis for a horizontally scalable
+// deployment.
+type Memory struct {
+	mu sync.Mutex
+	m  map[string]PendingMerge
+}
+
+// NewMemory builds an empty Memory store.
+func NewMemory() *Memory {
+	return &Memory{m: make(map[string]PendingMerge)}
+}
+
+func (s *Memory) Save(project string, mrIID int, pm PendingMerge, ttl time.Duration) error {
+	k := key(project, mrIID)
+
+	s.mu.Lock()
+	s.m[k] = pm
+	s.mu.Unlock()
+
+	if ttl > 0 {
+		time.AfterFunc(ttl, func() {
+			s.mu.Lock()
+			delete(s.m, k)
+			s.mu.Unlock()
+		})
+	}
+	return nil
+}
+
+func (s *Memory) Take(project string, mrIID int) (PendingMerge, bool, error) {
+	k := key(project, mrIID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pm, ok := s.m[k]
+	if ok {
+		delete(s.m, k)
+	}
+	return pm, ok, nil
+}
+
+func (s *Memory) List() (map[string]PendingMerge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make(map[string]PendingMerge, len(s.m))
+	for k, pm := range s.m {
+		out[k] = pm
+	}
+	return out, nil
+}
+
+func (s *Memory) Reset() error {
+	s.mu.Lock()
+	s.m = make(map[string]PendingMerge)
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	redis "gopkg.in/redis.v5"
+)
+
+// Redis persists pending merges in a Redis instance so lgtm-bot survives
+// restarts and can run as more than one replica.
+type Redis struct {
+	client *redis.Client
+}
+
+// takeScript atomically fetches and deletes the value at KEYS[1], so two
+// concurrent Take calls for the same pending merge can't both see it
+// before either deletes it (redis.v5 predates GETDEL).
+var takeScript = redis.NewScript(`
+local v = redis.call("GET", KEYS[1])
+if v then
+	redis.call("DEL", KEYS[1])
+end
+return v
+`)
+
+// NewRedis connects to the Redis instance at addr, e.g.
+// "redis://localhost:6379/0".
+func NewRedis(addr string) (*Redis, error) {
+	opt, err := redis.ParseURL(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	client := redis.NewClient(opt)
+	if err := client.Ping().Err(); err != nil {
+		return nil, err
+	}
+	return &Redis{client: client}, nil
+}
+
+func (s *Redis) Save(project string, mrIID int, pm PendingMerge, ttl time.Duration) error {
+	data, err := json.Marshal(pm)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(key(project, mrIID), data, ttl).Err()
+}
+
+func (s *Redis) Take(project string, mrIID int) (PendingMerge, bool, error) {
+	k := key(project, mrIID)
+
+	v, err := takeScript.Run(s.client, []string{k}).Result()
+	if err == redis.Nil {
+		return PendingMerge{}, false, nil
+	}
+	if err != nil {
+		return PendingMerge{}, false, err
+	}
+	if v == nil {
+		return PendingMerge{}, false, nil
+	}
+
+	var pm PendingMerge
+	if err := json.Unmarshal([]byte(v.(string)), &pm); err != nil {
+		return PendingMerge{}, false, err
+	}
+	return pm, true, nil
+}
+
+func (s *Redis) List() (map[string]PendingMerge, error) {
+	keys, err := s.client.Keys(keyPrefix + "*").Result()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]PendingMerge, len(keys))
+	for _, k := range keys {
+		data, err := s.client.Get(k).Bytes()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var pm PendingMerge
+		if err := json.Unmarshal(data, &pm); err != nil {
+			return nil, err
+		}
+		out[k] = pm
+	}
+	return out, nil
+}
+
+func (s *Redis) Reset() error {
+	keys, err := s.client.Keys(keyPrefix + "*").Result()
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return s.client.Del(keys...).Err()
+}
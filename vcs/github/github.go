@@ -0,0 +1,233 @@
+// Package github implements vcs.Provider against the GitHub REST API.
+//
+// Like Gitea, GitHub delivers pull request comments as issue_comment
+// events; the issue's pull_request field distinguishes them from plain
+// issue comments.
+package github
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/holys/lgtm-gitlab/vcs"
+)
+
+// DefaultBaseURL is used when no base URL is configured, i.e. for
+// github.com rather than a GitHub Enterprise instance.
+const DefaultBaseURL = "https://api.github.com"
+
+// Provider talks to GitHub or a GitHub Enterprise instance.
+type Provider struct {
+	baseURL *url.URL
+	token   string
+}
+
+// New builds a github.Provider against baseURL (DefaultBaseURL for
+// github.com), authenticating with the given personal access token.
+func New(baseURL, token string) (*Provider, error) {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Provider{baseURL: u, token: token}, nil
+}
+
+func (p *Provider) Name() string { return "github" }
+
+type issueCommentEvent struct {
+	Action string `json:"action"`
+	Issue  struct {
+		Number      int             `json:"number"`
+		PullRequest json.RawMessage `json:"pull_request"`
+		User        struct {
+			ID    int    `json:"id"`
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"issue"`
+	Comment struct {
+		Body string `json:"body"`
+	} `json:"comment"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+func (p *Provider) ParseWebhook(r *http.Request) (vcs.Event, error) {
+	var ev issueCommentEvent
+	if err := json.NewDecoder(r.Body).Decode(&ev); err != nil {
+		return vcs.Event{}, err
+	}
+
+	if ev.Action != "created" || len(ev.Issue.PullRequest) == 0 {
+		return vcs.Event{}, vcs.ErrUnhandledEvent
+	}
+
+	return vcs.Event{
+		Kind:                 vcs.KindNote,
+		NoteableType:         vcs.NoteableTypeMergeRequest,
+		Note:                 ev.Comment.Body,
+		Project:              ev.Repository.FullName,
+		MergeRequestIID:      ev.Issue.Number,
+		MergeRequestAuthorID: ev.Issue.User.ID,
+		// issue_comment carries no mergeable_state, so treat the PR as
+		// mergeable and let AcceptMergeRequest fail loudly if it isn't.
+		MergeStatus: vcs.StatusCanBeMerged,
+	}, nil
+}
+
+// url builds an API URL from format, appended to the base URL's path
+// (e.g. a GitHub Enterprise /api/v3); anything after a '?' in the
+// formatted string becomes the query rather than part of the path.
+func (p *Provider) url(format string, a ...interface{}) string {
+	u := *p.baseURL
+	s := fmt.Sprintf(format, a...)
+	if i := strings.IndexByte(s, '?'); i >= 0 {
+		s, u.RawQuery = s[:i], s[i+1:]
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + s
+	return u.String()
+}
+
+func (p *Provider) do(method, rawurl string, body []byte) (*http.Response, error) {
+	var reader *bytes.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, rawurl, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", "token "+p.token)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	return http.DefaultClient.Do(req)
+}
+
+// notesPageSize is the page size requested from GitHub's paginated list
+// endpoints (its default is 30); a page shorter than this means there's
+// no next page.
+const notesPageSize = 100
+
+// ListNotes walks every page of the pull request's comments: a PR with
+// more comments than a single page would otherwise silently lose older
+// approvals, undercounting approvers.
+func (p *Provider) ListNotes(project string, mergeRequestIID int) ([]vcs.Note, error) {
+	var out []vcs.Note
+	for page := 1; ; page++ {
+		resp, err := p.do("GET", p.url("/repos/%s/issues/%d/comments?page=%d&per_page=%d", project, mergeRequestIID, page, notesPageSize), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("list notes: unexpected status %d", resp.StatusCode)
+		}
+
+		var raw []struct {
+			Body string `json:"body"`
+			User struct {
+				ID    int    `json:"id"`
+				Login string `json:"login"`
+			} `json:"user"`
+			CreatedAt time.Time `json:"created_at"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&raw)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, n := range raw {
+			out = append(out, vcs.Note{
+				AuthorID:       n.User.ID,
+				AuthorUsername: n.User.Login,
+				Body:           n.Body,
+				CreatedAt:      n.CreatedAt,
+			})
+		}
+		if len(raw) < notesPageSize {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (p *Provider) AcceptMergeRequest(project string, mergeRequestIID int, removeSourceBranch bool) error {
+	bodyBytes, err := json.Marshal(map[string]string{"merge_method": "merge"})
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.do("PUT", p.url("/repos/%s/pulls/%d/merge", project, mergeRequestIID), bodyBytes)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("accept merge request: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (p *Provider) PostComment(project string, mergeRequestIID int, body string) error {
+	bodyBytes, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.do("POST", p.url("/repos/%s/issues/%d/comments", project, mergeRequestIID), bodyBytes)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("post comment: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (p *Provider) GetFile(project, path string) ([]byte, error) {
+	resp, err := p.do("GET", p.url("/repos/%s/contents/%s", project, path), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, vcs.ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get %s: unexpected status %d", path, resp.StatusCode)
+	}
+
+	var file struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
+		return nil, err
+	}
+
+	if file.Encoding != "base64" {
+		return []byte(file.Content), nil
+	}
+	return base64.StdEncoding.DecodeString(file.Content)
+}
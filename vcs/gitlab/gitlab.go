@@ -0,0 +1,189 @@
+// Package gitlab implements vcs.Provider on top of the go-gitlab client,
+// against the GitLab v4 API.
+package gitlab
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	gogitlab "github.com/xanzy/go-gitlab"
+
+	"github.com/holys/lgtm-gitlab/vcs"
+)
+
+// Provider talks to a single GitLab instance.
+type Provider struct {
+	client *gogitlab.Client
+}
+
+// New builds a gitlab.Provider for the GitLab instance at baseURL,
+// authenticating with privateToken (see
+// https://your.gitlab.com/profile/account).
+func New(baseURL, privateToken string) (*Provider, error) {
+	client, err := gogitlab.NewClient(privateToken, gogitlab.WithBaseURL(baseURL))
+	if err != nil {
+		return nil, err
+	}
+	return &Provider{client: client}, nil
+}
+
+func (p *Provider) Name() string { return "gitlab" }
+
+// VerifyWebhook checks the X-Gitlab-Token header GitLab sends on every
+// webhook request against secret, in constant time.
+func (p *Provider) VerifyWebhook(r *http.Request, secret string) bool {
+	token := r.Header.Get("X-Gitlab-Token")
+	return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
+
+func (p *Provider) ParseWebhook(r *http.Request) (vcs.Event, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return vcs.Event{}, err
+	}
+
+	var kind struct {
+		ObjectKind string `json:"object_kind"`
+	}
+	if err := json.Unmarshal(body, &kind); err != nil {
+		return vcs.Event{}, err
+	}
+
+	switch kind.ObjectKind {
+	case "note":
+		var ev gogitlab.MergeCommentEvent
+		if err := json.Unmarshal(body, &ev); err != nil {
+			return vcs.Event{}, err
+		}
+		if ev.ObjectAttributes.NoteableType != vcs.NoteableTypeMergeRequest {
+			return vcs.Event{}, vcs.ErrUnhandledEvent
+		}
+		// GitLab's "Comment on merge request" webhook never sends
+		// merge_params, so MergeParams is nil here; only the pipeline
+		// webhook's merge request payload populates it.
+		var removeSourceBranch bool
+		if ev.MergeRequest.MergeParams != nil {
+			removeSourceBranch = ev.MergeRequest.MergeParams.ForceRemoveSourceBranch
+		}
+		return vcs.Event{
+			Kind:                 vcs.KindNote,
+			NoteableType:         ev.ObjectAttributes.NoteableType,
+			Note:                 ev.ObjectAttributes.Note,
+			Project:              strconv.Itoa(ev.ProjectID),
+			MergeRequestIID:      ev.MergeRequest.IID,
+			MergeRequestAuthorID: ev.MergeRequest.AuthorID,
+			MergeStatus:          ev.MergeRequest.MergeStatus,
+			RemoveSourceBranch:   removeSourceBranch,
+		}, nil
+	case "pipeline":
+		var ev gogitlab.PipelineEvent
+		if err := json.Unmarshal(body, &ev); err != nil {
+			return vcs.Event{}, err
+		}
+		return vcs.Event{
+			Kind:           vcs.KindPipeline,
+			Project:        strconv.Itoa(ev.Project.ID),
+			Ref:            ev.ObjectAttributes.Ref,
+			PipelineStatus: ev.ObjectAttributes.Status,
+		}, nil
+	default:
+		return vcs.Event{}, vcs.ErrUnhandledEvent
+	}
+}
+
+// ListNotes walks every page of the MR's notes: an MR with more notes
+// than the API's default page size would otherwise silently lose older
+// approvals, undercounting approvers.
+func (p *Provider) ListNotes(project string, mergeRequestIID int) ([]vcs.Note, error) {
+	opt := &gogitlab.ListMergeRequestNotesOptions{
+		ListOptions: gogitlab.ListOptions{PerPage: 100},
+	}
+
+	var out []vcs.Note
+	for {
+		notes, resp, err := p.client.Notes.ListMergeRequestNotes(project, mergeRequestIID, opt)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range notes {
+			out = append(out, vcs.Note{
+				AuthorID:       n.Author.ID,
+				AuthorUsername: n.Author.Username,
+				Body:           n.Body,
+				CreatedAt:      *n.CreatedAt,
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return out, nil
+}
+
+func (p *Provider) AcceptMergeRequest(project string, mergeRequestIID int, removeSourceBranch bool) error {
+	_, _, err := p.client.MergeRequests.AcceptMergeRequest(project, mergeRequestIID, &gogitlab.AcceptMergeRequestOptions{
+		ShouldRemoveSourceBranch: gogitlab.Bool(removeSourceBranch),
+	})
+	return err
+}
+
+// AcceptWhenPipelineSucceeds queues the merge request to land
+// automatically once its pipeline succeeds, for MRs whose CI is still
+// running when the LGTM threshold is met.
+func (p *Provider) AcceptWhenPipelineSucceeds(project string, mergeRequestIID int, removeSourceBranch bool) error {
+	_, _, err := p.client.MergeRequests.AcceptMergeRequest(project, mergeRequestIID, &gogitlab.AcceptMergeRequestOptions{
+		ShouldRemoveSourceBranch:  gogitlab.Bool(removeSourceBranch),
+		MergeWhenPipelineSucceeds: gogitlab.Bool(true),
+	})
+	return err
+}
+
+// FindMergeRequestBySourceBranch returns the open merge request whose
+// source branch is ref, used to resolve a Pipeline Hook event (which
+// only carries a ref) back to the merge request awaiting its LGTMs.
+func (p *Provider) FindMergeRequestBySourceBranch(project, ref string) (int, int, error) {
+	opened := "opened"
+	mrs, _, err := p.client.MergeRequests.ListProjectMergeRequests(project, &gogitlab.ListProjectMergeRequestsOptions{
+		State:        &opened,
+		SourceBranch: &ref,
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(mrs) == 0 {
+		return 0, 0, vcs.ErrNotFound
+	}
+	return mrs[0].IID, mrs[0].Author.ID, nil
+}
+
+func (p *Provider) PostComment(project string, mergeRequestIID int, body string) error {
+	_, _, err := p.client.Notes.CreateMergeRequestNote(project, mergeRequestIID, &gogitlab.CreateMergeRequestNoteOptions{
+		Body: gogitlab.String(body),
+	})
+	return err
+}
+
+// GetFile fetches path from project's actual default branch (not
+// necessarily "master" — GitLab has defaulted new projects to "main"
+// for years), so .lgtm.yml overrides are picked up regardless of what
+// the project calls its default branch.
+func (p *Provider) GetFile(project, path string) ([]byte, error) {
+	proj, _, err := p.client.Projects.GetProject(project, nil)
+	if err != nil {
+		return nil, fmt.Errorf("get project %s: %s", project, err.Error())
+	}
+
+	f, resp, err := p.client.RepositoryFiles.GetRawFile(project, path, &gogitlab.GetRawFileOptions{Ref: &proj.DefaultBranch})
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, vcs.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %s", path, err.Error())
+	}
+	return f, nil
+}
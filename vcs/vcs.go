@@ -0,0 +1,109 @@
+// Package vcs abstracts over the git hosting services lgtm-bot can
+// watch for approvals, so a single deployment can serve a fleet that
+// mixes GitLab, Gitea and GitHub projects.
+package vcs
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Event kinds and noteable types, mirrored across providers so the
+// LGTM logic in the main package never sees a provider-specific type.
+const (
+	KindNote     = "note"
+	KindPipeline = "pipeline"
+
+	NoteableTypeMergeRequest = "MergeRequest"
+
+	StatusCanBeMerged = "can_be_merged"
+)
+
+// ErrUnhandledEvent is returned by ParseWebhook for payloads the caller
+// should silently ignore (wrong event type, ping/ready checks, etc).
+var ErrUnhandledEvent = errors.New("vcs: unhandled webhook event")
+
+// ErrNotFound is returned by GetFile when the path does not exist, and
+// by FindMergeRequestBySourceBranch when no open merge request matches.
+var ErrNotFound = errors.New("vcs: file not found")
+
+// Note is a single comment on a merge/pull request.
+type Note struct {
+	AuthorID       int
+	AuthorUsername string
+	Body           string
+	CreatedAt      time.Time
+}
+
+// Event is the provider-agnostic representation of a webhook event: a
+// note (comment) on a merge/pull request, or for providers that support
+// it, a pipeline/CI status change.
+type Event struct {
+	Kind         string
+	NoteableType string
+	Note         string
+
+	// Project identifies the repository: GitLab uses its numeric
+	// project id (stringified), Gitea and GitHub use "owner/repo".
+	Project string
+
+	MergeRequestIID      int // project-scoped number, used for API calls and logging
+	MergeRequestAuthorID int
+	MergeStatus          string
+	RemoveSourceBranch   bool
+
+	// Ref and PipelineStatus are set on KindPipeline events.
+	Ref            string
+	PipelineStatus string
+}
+
+// Provider is implemented once per git hosting service. It translates
+// that service's webhooks and REST API into the provider-agnostic
+// shapes above so checkLgtm only needs to be written once.
+type Provider interface {
+	// Name identifies the provider, e.g. "gitlab".
+	Name() string
+
+	// ParseWebhook decodes an incoming webhook request into an Event.
+	// It returns ErrUnhandledEvent for requests that represent neither a
+	// note on a merge/pull request nor, for providers that support it, a
+	// pipeline/CI status change.
+	ParseWebhook(r *http.Request) (Event, error)
+
+	ListNotes(project string, mergeRequestIID int) ([]Note, error)
+	AcceptMergeRequest(project string, mergeRequestIID int, removeSourceBranch bool) error
+	PostComment(project string, mergeRequestIID int, body string) error
+
+	// GetFile fetches a file from the repository's default branch. It
+	// returns ErrNotFound if the path does not exist.
+	GetFile(project, path string) ([]byte, error)
+}
+
+// PipelineMerger is implemented by providers whose merge API can queue a
+// merge request to land automatically once CI succeeds, e.g. GitLab's
+// merge_when_pipeline_succeeds.
+type PipelineMerger interface {
+	Provider
+	AcceptWhenPipelineSucceeds(project string, mergeRequestIID int, removeSourceBranch bool) error
+}
+
+// BranchMergeRequestFinder is implemented by providers that can resolve
+// the open merge/pull request for a source branch. It is needed to react
+// to a pipeline/CI webhook, which identifies a branch but not a merge
+// request.
+type BranchMergeRequestFinder interface {
+	Provider
+	FindMergeRequestBySourceBranch(project, ref string) (mergeRequestIID, authorID int, err error)
+}
+
+// WebhookVerifier is implemented by providers whose webhooks carry a
+// shared-secret header, so forged requests can be rejected before the
+// body is even decoded, e.g. GitLab's X-Gitlab-Token.
+type WebhookVerifier interface {
+	Provider
+	// VerifyWebhook reports whether r carries secret in the header this
+	// provider uses for webhook authentication. Comparison must run in
+	// constant time.
+	VerifyWebhook(r *http.Request, secret string) bool
+}